@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ChallengeKind identifies which anti-bot interstitial detectChallenge saw.
+type ChallengeKind string
+
+const (
+	ChallengeNone       ChallengeKind = ""
+	ChallengeCloudflare ChallengeKind = "cloudflare"
+	ChallengeDatadome   ChallengeKind = "datadome"
+	ChallengePerimeterX ChallengeKind = "perimeterx"
+)
+
+const (
+	maxChallengeAttempts = 3
+	challengeWaitTimeout = 20 * time.Second
+	challengePollEvery   = 500 * time.Millisecond
+)
+
+// clearanceJar stashes successful challenge-clearance cookies per host so
+// subsequent tag pages on the same host can skip the challenge entirely.
+// It's shared across every worker goroutine.
+type clearanceJar struct {
+	mu     sync.RWMutex
+	byHost map[string][]*proto.NetworkCookie
+}
+
+var clearance = &clearanceJar{byHost: make(map[string][]*proto.NetworkCookie)}
+
+func (j *clearanceJar) Get(host string) []*proto.NetworkCookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.byHost[host]
+}
+
+func (j *clearanceJar) Put(host string, cookies []*proto.NetworkCookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.byHost[host] = cookies
+}
+
+// detectChallenge inspects page for a known anti-bot interstitial: a
+// Cloudflare "Just a moment…" page (by title or cf_chl_opt/__cf_chl_ query
+// params or challenge cookie), or a Datadome/PerimeterX challenge cookie.
+// rod doesn't expose response headers on *rod.Page, so this can't key off
+// Cloudflare's cf-mitigated response header -- only the signals above.
+func detectChallenge(page *rod.Page) ChallengeKind {
+	if u := page.MustInfo().URL; strings.Contains(u, "cf_chl_opt") || strings.Contains(u, "__cf_chl_") {
+		return ChallengeCloudflare
+	}
+
+	if title, err := page.Eval(`() => document.title`); err == nil {
+		t := title.Value.Str()
+		if strings.Contains(t, "Just a moment") || strings.Contains(t, "Checking your browser") {
+			return ChallengeCloudflare
+		}
+	}
+
+	for _, c := range page.MustCookies() {
+		switch {
+		case strings.HasPrefix(c.Name, "__cf_chl_"):
+			return ChallengeCloudflare
+		case strings.HasPrefix(c.Name, "dd_cookie_test_"):
+			return ChallengeDatadome
+		case strings.HasPrefix(c.Name, "_px"):
+			return ChallengePerimeterX
+		}
+	}
+
+	return ChallengeNone
+}
+
+// awaitClearance holds page open for up to challengeWaitTimeout, polling
+// for document.title to change away from the interstitial and for the
+// challenge to clear (detectChallenge stops matching). It reports whether
+// clearance was observed within the deadline.
+func awaitClearance(page *rod.Page, kind ChallengeKind) bool {
+	initialTitle, _ := page.Eval(`() => document.title`)
+
+	deadline := time.Now().Add(challengeWaitTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(challengePollEvery)
+
+		title, err := page.Eval(`() => document.title`)
+		if err == nil && initialTitle != nil && title.Value.Str() != initialTitle.Value.Str() && detectChallenge(page) == ChallengeNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requeueAfterChallenge puts item back on frontier with an incremented
+// attempt counter, up to maxChallengeAttempts, after which it logs and
+// drops the item. If frontier is already closed, Requeue discards the item
+// without logging.
+func requeueAfterChallenge(frontier *Frontier, item *Item) {
+	if item.Attempts >= maxChallengeAttempts {
+		fmt.Printf("dropping %s after %d failed challenge attempt(s)\n", item.URL, item.Attempts)
+		return
+	}
+
+	item.Attempts++
+	frontier.Requeue(item)
+}
+
+func cookiesToParams(cookies []*proto.NetworkCookie) []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: c.SameSite,
+		})
+	}
+	return params
+}
@@ -0,0 +1,90 @@
+// Package adapter lets the crawler learn a new blog platform's DOM shape
+// without touching the crawl loop itself: a SiteAdapter owns every
+// site-specific CSS selector, and a Registry picks the right one by
+// hostname.
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Post is the platform-agnostic result of extracting an article. Content is
+// returned separately by ExtractPost since callers often stream it straight
+// to a file rather than hold it in memory alongside the metadata.
+type Post struct {
+	URL         string
+	Title       string
+	Author      string
+	PublishedAt string
+	Tags        []string
+}
+
+// SiteAdapter knows how to recognize a platform's URLs, seed an initial
+// crawl of it and pull a Post out of one of its article pages.
+type SiteAdapter interface {
+	// MatchURL reports whether u belongs to this adapter's platform.
+	MatchURL(u *url.URL) bool
+	// ExtractPost parses doc (already fetched from pageURL) into a Post and
+	// its plain-text content.
+	ExtractPost(doc *goquery.Document, pageURL string) (*Post, string, error)
+	// SeedURLs returns the pages a fresh crawl of this platform should
+	// start from (e.g. a tag index).
+	SeedURLs(ctx context.Context) ([]string, error)
+	// LinkSelector is the CSS selector used to collect further article
+	// links from a page belonging to this platform.
+	LinkSelector() string
+}
+
+// Registry resolves a URL to the SiteAdapter that should handle it. The
+// first registered adapter whose MatchURL matches wins, so more specific
+// adapters should be registered before general-purpose ones.
+type Registry struct {
+	mu       sync.RWMutex
+	adapters []SiteAdapter
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a to the registry.
+func (r *Registry) Register(a SiteAdapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters = append(r.adapters, a)
+}
+
+// All returns every registered adapter, in registration order.
+func (r *Registry) All() []SiteAdapter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]SiteAdapter, len(r.adapters))
+	copy(out, r.adapters)
+	return out
+}
+
+// For returns the first registered adapter matching rawURL.
+func (r *Registry) For(rawURL string) (SiteAdapter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.adapters {
+		if a.MatchURL(u) {
+			return a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("adapter: no site adapter registered for %s", u.Hostname())
+}
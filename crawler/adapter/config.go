@@ -0,0 +1,114 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// fieldSelector picks one DOM node out of the page and optionally reads one
+// of its attributes instead of its text content (e.g. a <time datetime=..>).
+type fieldSelector struct {
+	CSS  string `yaml:"css"`
+	Attr string `yaml:"attr,omitempty"`
+}
+
+func (f fieldSelector) valueFrom(doc *goquery.Document) string {
+	if f.CSS == "" {
+		return ""
+	}
+
+	sel := doc.Find(f.CSS).First()
+	if sel.Length() == 0 {
+		return ""
+	}
+
+	if f.Attr != "" {
+		v, _ := sel.Attr(f.Attr)
+		return strings.TrimSpace(v)
+	}
+
+	return strings.TrimSpace(sel.Text())
+}
+
+// configEntry is the declarative, user-authored description of a platform's
+// selectors, as loaded from YAML. It describes CSS selectors only -- XPath
+// is not supported by goquery and isn't worth a second dependency here.
+type configEntry struct {
+	Host         string        `yaml:"host"`
+	Title        fieldSelector `yaml:"title"`
+	Author       fieldSelector `yaml:"author"`
+	Date         fieldSelector `yaml:"date"`
+	Tags         string        `yaml:"tags_css"`
+	Content      string        `yaml:"content_css"`
+	LinkCSS      string        `yaml:"link_selector"`
+	SeedPageURLs []string      `yaml:"seeds"`
+}
+
+// configAdapter is a SiteAdapter driven entirely by a configEntry.
+type configAdapter struct {
+	entry configEntry
+}
+
+func (a configAdapter) MatchURL(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), a.entry.Host)
+}
+
+func (a configAdapter) ExtractPost(doc *goquery.Document, pageURL string) (*Post, string, error) {
+	p := &Post{
+		URL:         pageURL,
+		Title:       a.entry.Title.valueFrom(doc),
+		Author:      a.entry.Author.valueFrom(doc),
+		PublishedAt: a.entry.Date.valueFrom(doc),
+	}
+
+	if a.entry.Tags != "" {
+		doc.Find(a.entry.Tags).Each(func(_ int, s *goquery.Selection) {
+			if tag := strings.TrimSpace(s.Text()); tag != "" {
+				p.Tags = append(p.Tags, tag)
+			}
+		})
+	}
+
+	content := strings.TrimSpace(doc.Find(a.entry.Content).Text())
+
+	return p, content, nil
+}
+
+func (a configAdapter) SeedURLs(ctx context.Context) ([]string, error) {
+	return a.entry.SeedPageURLs, nil
+}
+
+func (a configAdapter) LinkSelector() string {
+	return a.entry.LinkCSS
+}
+
+// LoadFromYAML reads a list of declarative adapter configs (see
+// configEntry) and returns one SiteAdapter per entry, so operators can teach
+// the crawler a new blog platform without a Go code change.
+func LoadFromYAML(path string) ([]SiteAdapter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("adapter: reading %s: %w", path, err)
+	}
+
+	var entries []configEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("adapter: parsing %s: %w", path, err)
+	}
+
+	adapters := make([]SiteAdapter, 0, len(entries))
+	for _, e := range entries {
+		if e.Host == "" {
+			return nil, fmt.Errorf("adapter: entry missing required `host` field")
+		}
+		adapters = append(adapters, configAdapter{entry: e})
+	}
+
+	return adapters, nil
+}
@@ -0,0 +1,46 @@
+package adapter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DevTo is the built-in SiteAdapter for dev.to, ported from the selectors
+// the scraper originally hard-coded.
+type DevTo struct{}
+
+func (DevTo) MatchURL(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), "dev.to")
+}
+
+func (DevTo) ExtractPost(doc *goquery.Document, pageURL string) (*Post, string, error) {
+	p := &Post{URL: pageURL}
+
+	infoWrapper := doc.Find("div.crayons-article__header__meta")
+	p.Title = strings.TrimSpace(infoWrapper.Find("h1").Text())
+	p.Author = strings.TrimSpace(infoWrapper.Find("a.crayons-link").Text())
+	if timeEl := infoWrapper.Find("time"); timeEl.Length() > 0 {
+		if pubDate, exists := timeEl.Attr("datetime"); exists {
+			p.PublishedAt = pubDate
+		}
+	}
+
+	infoWrapper.Find("a.crayons-tag").Each(func(_ int, s *goquery.Selection) {
+		p.Tags = append(p.Tags, strings.TrimSpace(s.Text()))
+	})
+
+	content := strings.TrimSpace(doc.Find(".crayons-article__main").Text())
+
+	return p, content, nil
+}
+
+func (DevTo) SeedURLs(ctx context.Context) ([]string, error) {
+	return []string{"https://dev.to/tags"}, nil
+}
+
+func (DevTo) LinkSelector() string {
+	return "a[aria-labelledby]"
+}
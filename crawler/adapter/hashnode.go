@@ -0,0 +1,48 @@
+package adapter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Hashnode is the built-in SiteAdapter for hashnode.com and the
+// *.hashnode.dev custom domains Hashnode blogs are published under.
+type Hashnode struct{}
+
+func (Hashnode) MatchURL(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return strings.HasSuffix(host, "hashnode.com") || strings.HasSuffix(host, "hashnode.dev")
+}
+
+func (Hashnode) ExtractPost(doc *goquery.Document, pageURL string) (*Post, string, error) {
+	p := &Post{URL: pageURL}
+
+	p.Title = strings.TrimSpace(doc.Find("h1").First().Text())
+	p.Author = strings.TrimSpace(doc.Find("a[href*='/@']").First().Text())
+	if timeEl := doc.Find("time").First(); timeEl.Length() > 0 {
+		if pubDate, exists := timeEl.Attr("datetime"); exists {
+			p.PublishedAt = pubDate
+		}
+	}
+
+	doc.Find("a[href*='/tag/']").Each(func(_ int, s *goquery.Selection) {
+		if tag := strings.TrimSpace(s.Text()); tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	})
+
+	content := strings.TrimSpace(doc.Find("article").Text())
+
+	return p, content, nil
+}
+
+func (Hashnode) SeedURLs(ctx context.Context) ([]string, error) {
+	return []string{"https://hashnode.com/explore"}, nil
+}
+
+func (Hashnode) LinkSelector() string {
+	return "a[href*='/@']"
+}
@@ -0,0 +1,48 @@
+package adapter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Medium is the built-in SiteAdapter for medium.com and its subdomains
+// (e.g. username.medium.com). Publications on custom domains are not
+// matched.
+type Medium struct{}
+
+func (Medium) MatchURL(u *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), "medium.com")
+}
+
+func (Medium) ExtractPost(doc *goquery.Document, pageURL string) (*Post, string, error) {
+	p := &Post{URL: pageURL}
+
+	p.Title = strings.TrimSpace(doc.Find("article h1").First().Text())
+	p.Author = strings.TrimSpace(doc.Find("a[data-testid='authorName']").First().Text())
+	if timeEl := doc.Find("article time").First(); timeEl.Length() > 0 {
+		if pubDate, exists := timeEl.Attr("datetime"); exists {
+			p.PublishedAt = pubDate
+		}
+	}
+
+	doc.Find("a[href*='/tag/']").Each(func(_ int, s *goquery.Selection) {
+		if tag := strings.TrimSpace(s.Text()); tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	})
+
+	content := strings.TrimSpace(doc.Find("article").Text())
+
+	return p, content, nil
+}
+
+func (Medium) SeedURLs(ctx context.Context) ([]string, error) {
+	return []string{"https://medium.com/tag/programming"}, nil
+}
+
+func (Medium) LinkSelector() string {
+	return "a[href*='medium.com/']"
+}
@@ -1,32 +1,122 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
+	"iugstav.ipasques/adapter"
+	"iugstav.ipasques/progress"
 	"iugstav.ipasques/robot"
 )
 
+const (
+	storePath       = "frontier.db"
+	checkpointEvery = 10 * time.Second
+	robotsTTL       = 24 * time.Hour
+)
+
 func main() {
+	resume := flag.Bool("resume", false, "skip re-seeding from the site adapters and work only off the items left in frontier.db (pending items and the visited set are reloaded from frontier.db with or without this flag)")
+	ignoreRobots := flag.Bool("ignore-robots", false, "skip robots.txt checks (useful against infrastructure you control, e.g. in tests)")
+	silent := flag.Bool("silent", false, "suppress all progress output, including the final summary")
+	noProgress := flag.Bool("no-progress", false, "suppress the live progress bar, but still print the final summary")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "how long to wait for in-flight workers to drain on SIGINT/SIGTERM")
+	adapterConfig := flag.String("adapter-config", "", "path to a YAML file of declarative site adapters, loaded alongside the built-in ones")
+	flag.Parse()
+
 	rand.New(rand.NewSource(time.Now().UnixNano()))
 
+	registry := adapter.NewRegistry()
+	registry.Register(adapter.DevTo{})
+	registry.Register(adapter.Medium{})
+	registry.Register(adapter.Hashnode{})
+	if *adapterConfig != "" {
+		loaded, err := adapter.LoadFromYAML(*adapterConfig)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		for _, a := range loaded {
+			registry.Register(a)
+		}
+	}
+
 	w, err := robot.InitURLWriter("devto_urls.txt")
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	defer w.File.Close()
+	defer w.Close()
 
 	crawler := robot.InitCrawler()
 	defer crawler.Cleanup()
 
-	frontier := robot.NewCrawlerFrontier(1 * time.Second)
+	store, err := robot.NewBoltStore(storePath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	frontier, err := robot.NewPersistentFrontier(1*time.Second, store)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer frontier.Close()
 
-	crawler.GetTags(frontier)
+	robotsCache := robot.NewRobotsCache(robot.DefaultUserAgent, robotsTTL)
+	robotsCache.Bypass = *ignoreRobots
+	frontier.UseRobots(robotsCache)
+
+	checkpointTicker := time.NewTicker(checkpointEvery)
+	defer checkpointTicker.Stop()
+	checkpointDone := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-checkpointTicker.C:
+				if err := frontier.Checkpoint(); err != nil {
+					fmt.Println(err)
+				}
+			case <-checkpointDone:
+				return
+			}
+		}
+	}()
+	defer close(checkpointDone)
+
+	switch {
+	case *resume:
+		fmt.Println("resuming crawl from frontier.db")
+	case frontier.Pending() > 0:
+		fmt.Println("frontier.db has pending items from a previous run, resuming without -resume")
+	default:
+		crawler.GetTags(frontier, registry)
+	}
+
+	reporter := progress.NewReporter(0, *silent, *noProgress, 500*time.Millisecond)
+	reporter.Start()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	shutdownCh := make(chan struct{})
+	go func() {
+		sig := <-sigCh
+		fmt.Printf("received %v, draining in-flight workers (up to %s)...\n", sig, *shutdownTimeout)
+		frontier.Close()
+		close(shutdownCh)
+	}()
 
 	var wg sync.WaitGroup
 	for i := range robot.WORKER_COUNT {
@@ -44,11 +134,42 @@ func main() {
 				}
 
 				fmt.Printf("Worker %d at tag %s\n", id, item.URL)
-				robot.ProcessTag(id, item, frontier, browser, w)
+				if err := robot.ProcessTag(ctx, id, item, frontier, browser, w, registry); err != nil {
+					fmt.Println(err)
+					reporter.Failure()
+				} else {
+					reporter.Success()
+				}
+
+				select {
+				case <-shutdownCh:
+					fmt.Printf("Worker %d | Shutting down...\n", id)
+					return
+				default:
+				}
 			}
 		}(i, &wg)
 	}
 
 	frontier.Close()
-	wg.Wait()
+
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-shutdownCh:
+		select {
+		case <-workersDone:
+		case <-time.After(*shutdownTimeout):
+			fmt.Println("timed out waiting for workers to drain, cancelling in-flight requests")
+			cancel()
+			<-workersDone
+		}
+	}
+
+	reporter.Stop()
 }
@@ -2,21 +2,30 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"iugstav.ipasques/adapter"
+	"iugstav.ipasques/extract"
+	"iugstav.ipasques/progress"
 	"iugstav.ipasques/robot"
+	"iugstav.ipasques/warc"
 )
 
 const (
@@ -25,8 +34,21 @@ const (
 	INTERVAL       = 500 * time.Millisecond
 	RETRIES        = 3
 	BLOG_DIR       = "posts"
+	WARC_DIR       = "warc"
 )
 
+// outputFormat selects which archive(s) readPost's result is written to.
+type outputFormat string
+
+const (
+	formatCSV  outputFormat = "csv"
+	formatWARC outputFormat = "warc"
+	formatBoth outputFormat = "both"
+)
+
+func (f outputFormat) writesCSV() bool  { return f == formatCSV || f == formatBoth }
+func (f outputFormat) writesWARC() bool { return f == formatWARC || f == formatBoth }
+
 type writeTask struct {
 	path    string
 	content []byte
@@ -109,31 +131,72 @@ type Post struct {
 }
 
 func main() {
-	if len(os.Args) != 3 {
-		log.Fatalln("Usage: ./scraper <input-file> <output-file>")
+	format := flag.String("format", string(formatCSV), "output format: csv|warc|both")
+	warcDir := flag.String("warc-dir", WARC_DIR, "directory for rotated .warc.gz files (format=warc|both)")
+	warcMaxSize := flag.Int64("warc-max-size", 200*1024*1024, "rotate to a new .warc.gz file once this many uncompressed record bytes have been written to the current one (0 disables rotation)")
+	silent := flag.Bool("silent", false, "suppress all progress output, including the final summary")
+	noProgress := flag.Bool("no-progress", false, "suppress the live progress bar, but still print the final summary")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "how long to wait for in-flight workers to drain on SIGINT/SIGTERM")
+	adapterConfig := flag.String("adapter-config", "", "path to a YAML file of declarative site adapters, loaded alongside the built-in ones")
+	flag.Parse()
+
+	registry := adapter.NewRegistry()
+	registry.Register(adapter.DevTo{})
+	registry.Register(adapter.Medium{})
+	registry.Register(adapter.Hashnode{})
+	if *adapterConfig != "" {
+		loaded, err := adapter.LoadFromYAML(*adapterConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, a := range loaded {
+			registry.Register(a)
+		}
 	}
 
-	urls, err := readURLs(os.Args[1])
-	if err != nil {
-		log.Fatal(err)
+	out := outputFormat(*format)
+	if !out.writesCSV() && !out.writesWARC() {
+		log.Fatalf("invalid -format %q: must be csv, warc or both", *format)
 	}
 
-	output, err := os.Create(os.Args[2])
+	args := flag.Args()
+	if len(args) != 2 {
+		log.Fatalln("Usage: ./scraper [-format csv|warc|both] <input-file> <output-file>")
+	}
+
+	urls, err := readURLs(args[0])
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer output.Close()
 
-	if err := os.MkdirAll(BLOG_DIR, 0755); err != nil {
-		log.Fatalln(err)
+	var writer *csv.Writer
+	if out.writesCSV() {
+		output, err := os.Create(args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer output.Close()
+
+		if err := os.MkdirAll(BLOG_DIR, 0755); err != nil {
+			log.Fatalln(err)
+		}
+
+		writer = csv.NewWriter(output)
+		defer writer.Flush()
+		if err := writer.Write([]string{
+			"url", "title", "author", "published_at", "content_path", "tags",
+		}); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	writer := csv.NewWriter(output)
-	defer writer.Flush()
-	if err := writer.Write([]string{
-		"url", "title", "author", "published_at", "content_path", "tags",
-	}); err != nil {
-		log.Fatal(err)
+	var warcWriter *warc.Writer
+	if out.writesWARC() {
+		warcWriter, err = warc.NewWriter(*warcDir, "pages", *warcMaxSize)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer warcWriter.Close()
 	}
 
 	client := &http.Client{
@@ -149,6 +212,23 @@ func main() {
 	rateTicker := time.NewTicker(INTERVAL)
 	defer rateTicker.Stop()
 
+	robotsCache := robot.NewRobotsCache(robot.DefaultUserAgent, 24*time.Hour)
+
+	reporter := progress.NewReporter(len(urls), *silent, *noProgress, 500*time.Millisecond)
+	reporter.Start()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	shutdownCh := make(chan struct{})
+	go func() {
+		sig := <-sigCh
+		log.Printf("received %v, draining in-flight workers (up to %s)...\n", sig, *shutdownTimeout)
+		close(shutdownCh)
+	}()
+
 	var wg sync.WaitGroup
 	urlChan := make(chan string, MAX_NAVIGATORS*2)
 	results := make(chan *Post, MAX_NAVIGATORS*2)
@@ -156,11 +236,17 @@ func main() {
 	writerPool := newFileWriterPool(runtime.NumCPU())
 	for i := range MAX_NAVIGATORS {
 		wg.Add(1)
-		go navigate(i, &wg, client, urlChan, results, rateTicker, writerPool)
+		go navigate(ctx, i, &wg, client, urlChan, results, rateTicker, writerPool, warcWriter, out, robotsCache, reporter, registry)
 	}
 
+	resultsDone := make(chan struct{})
 	go func() {
+		defer close(resultsDone)
 		for post := range results {
+			if writer == nil {
+				continue
+			}
+
 			record := []string{
 				post.URL,
 				post.Title,
@@ -174,40 +260,99 @@ func main() {
 			}
 		}
 
-		writer.Flush()
+		if writer != nil {
+			writer.Flush()
+		}
 	}()
 
-	for _, u := range urls {
-		urlChan <- u
+	go func() {
+		defer close(urlChan)
+		for _, u := range urls {
+			select {
+			case urlChan <- u:
+			case <-shutdownCh:
+				log.Println("shutdown requested, no more URLs will be queued")
+				return
+			}
+		}
+	}()
+
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-shutdownCh:
+		select {
+		case <-workersDone:
+		case <-time.After(*shutdownTimeout):
+			log.Println("timed out waiting for workers to drain, cancelling in-flight requests")
+			cancel()
+			<-workersDone
+		}
 	}
 
-	close(urlChan)
-	wg.Wait()
 	close(results)
+	<-resultsDone
 	writerPool.Close()
+	reporter.Stop()
 }
 
-func navigate(workerID int, wg *sync.WaitGroup, client *http.Client, urls <-chan string, results chan<- *Post, ticker *time.Ticker, writer *fileWriterPool) {
+func navigate(ctx context.Context, workerID int, wg *sync.WaitGroup, client *http.Client, urls <-chan string, results chan<- *Post, ticker *time.Ticker, writer *fileWriterPool, warcWriter *warc.Writer, format outputFormat, robotsCache *robot.RobotsCache, reporter *progress.Reporter, registry *adapter.Registry) {
 	defer wg.Done()
 
 	log.Printf("worker %d starting\n", workerID)
 
 	for u := range urls {
+		if ctx.Err() != nil {
+			log.Printf("worker %d stopping: %v\n", workerID, ctx.Err())
+			return
+		}
+
+		if allowed, err := robotsCache.Allowed(u); err != nil {
+			log.Printf("worker=%d url=%s robots.txt check error: %v", workerID, u, err)
+		} else if !allowed {
+			log.Printf("worker=%d url=%s disallowed by robots.txt, skipping", workerID, u)
+			reporter.Failure()
+			continue
+		}
+
 		var post *Post
 		var content string
+		var req *http.Request
+		var res *http.Response
+		var raw []byte
 		var err error
 
 		for attempt := 1; attempt <= RETRIES; attempt++ {
-			<-ticker.C
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				break
+			}
 
-			post, content, err = readPost(client, u)
+			post, content, req, res, raw, err = readPost(ctx, client, u, registry)
 			if err == nil {
 				break
 			}
 
 			if attempt < RETRIES {
 				log.Printf("worker=%d url=%s attempt=%d error=%v, retrying", workerID, u, attempt, err)
-				time.Sleep(time.Duration(attempt+1) * time.Second)
+				select {
+				case <-time.After(time.Duration(attempt+1) * time.Second):
+				case <-ctx.Done():
+				}
+				if ctx.Err() != nil {
+					err = ctx.Err()
+					break
+				}
 				continue
 			}
 			break
@@ -215,66 +360,101 @@ func navigate(workerID int, wg *sync.WaitGroup, client *http.Client, urls <-chan
 
 		if err != nil || post == nil {
 			fmt.Printf("worker %d failed on url navigation %s : %v\n", workerID, u, err)
+			reporter.Failure()
 			continue
 		}
 
-		contentPath, err := urlToFilename(u)
-		if err != nil {
-			log.Printf("worker=%d failed to parse url %s: %v\n", workerID, u, err)
+		if ctx.Err() != nil {
+			log.Printf("worker %d stopping before recording %s: %v\n", workerID, u, ctx.Err())
+			return
+		}
+
+		if format.writesWARC() {
+			if err := warcWriter.WriteExchange(u, req, res, raw); err != nil {
+				log.Printf("worker=%d url=%s warc write error: %v\n", workerID, u, err)
+			}
+		}
+
+		if links, err := extract.GetLinks(res, raw); err == nil {
+			var navigational, assets int
+			for _, l := range links {
+				if l.Asset {
+					assets++
+				} else {
+					navigational++
+				}
+			}
+			log.Printf("worker=%d url=%s discovered %d navigational / %d asset link(s)", workerID, u, navigational, assets)
 		}
-		writer.Write(contentPath, []byte(content))
-		post.ContentPath = contentPath
 
-		log.Printf("worker %d extracted successfully %s", workerID, contentPath)
+		if format.writesCSV() {
+			contentPath, err := urlToFilename(u)
+			if err != nil {
+				log.Printf("worker=%d failed to parse url %s: %v\n", workerID, u, err)
+			}
+			writer.Write(contentPath, []byte(content))
+			post.ContentPath = contentPath
+		}
+
+		log.Printf("worker %d extracted successfully %s", workerID, u)
+		reporter.Success()
 		results <- post
 	}
 
 }
 
-func readPost(c *http.Client, url string) (*Post, string, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// readPost fetches url and returns the parsed Post alongside the raw
+// request/response pair and body bytes, so callers can archive the exchange
+// (e.g. to WARC) without re-fetching it.
+func readPost(ctx context.Context, c *http.Client, url string, registry *adapter.Registry) (*Post, string, *http.Request, *http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, nil, nil, err
 	}
 	req.Header.Set("User-Agent", robot.PickUA())
 
 	res, err := c.Do(req)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, nil, nil, err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, "", fmt.Errorf("invalid status code %d\n", res.StatusCode)
+		return nil, "", nil, nil, nil, fmt.Errorf("invalid status code %d\n", res.StatusCode)
 	}
 
-	return parse(res.Body, url)
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", nil, nil, nil, err
+	}
+
+	post, content, err := parse(bytes.NewReader(raw), url, registry)
+	return post, content, req, res, raw, err
 }
 
-func parse(body io.Reader, url string) (*Post, string, error) {
-	doc, err := goquery.NewDocumentFromReader(body)
+func parse(body io.Reader, url string, registry *adapter.Registry) (*Post, string, error) {
+	siteAdapter, err := registry.For(url)
 	if err != nil {
 		return nil, "", err
 	}
 
-	p := &Post{URL: url}
-
-	infoWrapper := doc.Find("div.crayons-article__header__meta")
-	p.Title = strings.TrimSpace(infoWrapper.Find("h1").Text())
-	p.Author = strings.TrimSpace(infoWrapper.Find("a.crayons-link").Text())
-	if timeEl := infoWrapper.Find("time"); timeEl.Length() > 0 {
-		if pubDate, exists := timeEl.Attr("datetime"); exists {
-			p.PublishedAt = pubDate
-		}
+	doc, err := goquery.NewDocumentFromReader(body)
+	if err != nil {
+		return nil, "", err
 	}
 
-	var tags []string
-	infoWrapper.Find("a.crayons-tag").Each(func(_ int, s *goquery.Selection) {
-		tags = append(tags, strings.TrimSpace(s.Text()))
-	})
-	p.Tags = strings.Join(tags, "/")
+	raw, content, err := siteAdapter.ExtractPost(doc, url)
+	if err != nil {
+		return nil, "", err
+	}
 
-	content := strings.TrimSpace(doc.Find(".crayons-article__main").Text())
+	p := &Post{
+		URL:         raw.URL,
+		Title:       raw.Title,
+		Author:      raw.Author,
+		PublishedAt: raw.PublishedAt,
+		Tags:        strings.Join(raw.Tags, "/"),
+	}
 
 	return p, content, nil
 }
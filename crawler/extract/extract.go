@@ -0,0 +1,152 @@
+// Package extract discovers outbound links from a fetched page, dispatching
+// on Content-Type so both HTML documents and CSS stylesheets contribute to
+// the crawl frontier.
+package extract
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Outlink is a single link discovered on a page, resolved to an absolute
+// URL relative to the page it was found on.
+type Outlink struct {
+	URL       string
+	SourceTag string // e.g. "a[href]", "img[src]", "css:url()"
+	Asset     bool   // true for images/scripts/stylesheets, false for navigational links
+}
+
+type selectorSpec struct {
+	selector string
+	attr     string
+	asset    bool
+}
+
+// htmlSelectors is the table of {tag, attr} pairs walked for every HTML
+// document. Order doesn't matter; every match is reported once.
+var htmlSelectors = []selectorSpec{
+	{"a[href]", "href", false},
+	{"area[href]", "href", false},
+	{"link[href]", "href", false},
+	{"img[src]", "src", true},
+	{"script[src]", "src", true},
+	{"iframe[src]", "src", false},
+	{"source[src]", "src", true},
+	{"source[srcset]", "srcset", true},
+}
+
+// cssURLPattern matches url(...) references in a stylesheet, including
+// background images and @import url(...) targets. A bare-string
+// @import "x.css" is not matched.
+var cssURLPattern = regexp.MustCompile(`url\(\s*["']?([^'"\)]+)["']?\s*\)`)
+
+// GetLinks extracts outbound links from resp's body, dispatching on its
+// Content-Type header. Absolute URLs are resolved against resp.Request.URL.
+func GetLinks(resp *http.Response, body []byte) ([]Outlink, error) {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return nil, fmt.Errorf("extract: response has no associated request URL")
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "text/html"), strings.Contains(contentType, "application/xhtml"):
+		return ExtractHTML(body, resp.Request.URL)
+	case strings.Contains(contentType, "text/css"):
+		return ExtractCSS(body, resp.Request.URL)
+	default:
+		return nil, fmt.Errorf("extract: unsupported content type %q", contentType)
+	}
+}
+
+// ExtractHTML walks htmlSelectors over an HTML document, resolving every
+// matched attribute against base. Exported separately from GetLinks so
+// callers that already have rendered HTML (e.g. a go-rod page) without an
+// *http.Response can reuse the same selector table.
+func ExtractHTML(body []byte, base *url.URL) ([]Outlink, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("extract: parsing HTML: %w", err)
+	}
+
+	var links []Outlink
+	for _, spec := range htmlSelectors {
+		doc.Find(spec.selector).Each(func(_ int, s *goquery.Selection) {
+			if spec.attr == "srcset" {
+				links = append(links, parseSrcset(s, base, spec.selector)...)
+				return
+			}
+
+			value, exists := s.Attr(spec.attr)
+			if !exists || strings.TrimSpace(value) == "" {
+				return
+			}
+
+			if resolved, err := resolve(base, value); err == nil {
+				links = append(links, Outlink{URL: resolved, SourceTag: spec.selector, Asset: spec.asset})
+			}
+		})
+	}
+
+	return links, nil
+}
+
+func parseSrcset(s *goquery.Selection, base *url.URL, sourceTag string) []Outlink {
+	value, exists := s.Attr("srcset")
+	if !exists {
+		return nil
+	}
+
+	var links []Outlink
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(strings.TrimSpace(candidate))
+		if len(fields) == 0 {
+			continue
+		}
+		if resolved, err := resolve(base, fields[0]); err == nil {
+			links = append(links, Outlink{URL: resolved, SourceTag: sourceTag, Asset: true})
+		}
+	}
+
+	return links
+}
+
+// ExtractCSS applies cssURLPattern to a stylesheet body, catching background
+// images and @import targets written as url(...). base resolves any
+// relative reference.
+func ExtractCSS(body []byte, base *url.URL) ([]Outlink, error) {
+	matches := cssURLPattern.FindAllSubmatch(body, -1)
+
+	var links []Outlink
+	for _, m := range matches {
+		if len(m) < 2 {
+			continue
+		}
+
+		raw := strings.TrimSpace(string(m[1]))
+		if raw == "" {
+			continue
+		}
+
+		if resolved, err := resolve(base, raw); err == nil {
+			links = append(links, Outlink{URL: resolved, SourceTag: "css:url()", Asset: true})
+		}
+	}
+
+	return links, nil
+}
+
+func resolve(base *url.URL, ref string) (string, error) {
+	refURL, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+
+	resolved := base.ResolveReference(refURL)
+	resolved.Fragment = ""
+	return resolved.String(), nil
+}
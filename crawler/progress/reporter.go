@@ -0,0 +1,118 @@
+// Package progress renders a terminal progress bar for long-running crawls
+// and tracks basic success/failure counters.
+package progress
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Reporter tracks processed/success/failure counts and renders them to
+// stderr on a ticker. It is safe for concurrent use by multiple workers.
+type Reporter struct {
+	total int64 // 0 means unknown (e.g. a tag crawl with no fixed URL count)
+
+	processed atomic.Int64
+	success   atomic.Int64
+	failure   atomic.Int64
+
+	start      time.Time
+	interval   time.Duration
+	silent     bool // suppress all output, including the final summary
+	noProgress bool // suppress the live bar, but still print the final summary
+	done       chan struct{}
+}
+
+// NewReporter builds a Reporter. total is the expected item count (used for
+// a percentage/ETA) or 0 if unknown.
+func NewReporter(total int, silent, noProgress bool, interval time.Duration) *Reporter {
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	return &Reporter{
+		total:      int64(total),
+		interval:   interval,
+		silent:     silent,
+		noProgress: noProgress,
+		done:       make(chan struct{}),
+	}
+}
+
+// Success records a successful item and advances the bar.
+func (r *Reporter) Success() {
+	r.processed.Add(1)
+	r.success.Add(1)
+}
+
+// Failure records a failed item and advances the bar.
+func (r *Reporter) Failure() {
+	r.processed.Add(1)
+	r.failure.Add(1)
+}
+
+// Start begins rendering the bar on a ticker. Call Stop when the crawl ends.
+func (r *Reporter) Start() {
+	r.start = time.Now()
+	if r.silent || r.noProgress {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.render()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts rendering and prints a final summary line.
+func (r *Reporter) Stop() {
+	close(r.done)
+	if r.silent {
+		return
+	}
+
+	r.render()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "done in %s | processed=%d success=%d failure=%d\n",
+		time.Since(r.start).Round(time.Second), r.processed.Load(), r.success.Load(), r.failure.Load())
+}
+
+func (r *Reporter) render() {
+	elapsed := time.Since(r.start)
+	processed := r.processed.Load()
+
+	rate := 0.0
+	if elapsed.Seconds() > 0 {
+		rate = float64(processed) / elapsed.Seconds()
+	}
+
+	if r.total > 0 {
+		pct := float64(processed) / float64(r.total) * 100
+		eta := "n/a"
+		if rate > 0 {
+			remaining := r.total - processed
+			if remaining < 0 {
+				remaining = 0
+			}
+			eta = time.Duration(float64(remaining) / rate * float64(time.Second)).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(os.Stderr, "\r[%5.1f%%] %d/%d ok=%d fail=%d %.1f req/s ETA %s ",
+			pct, processed, r.total, r.success.Load(), r.failure.Load(), rate, eta)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\r%d processed ok=%d fail=%d %.1f req/s ",
+		processed, r.success.Load(), r.failure.Load(), rate)
+}
@@ -2,6 +2,7 @@ package robot
 
 import (
 	"container/heap"
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
@@ -12,6 +13,8 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
+	"iugstav.ipasques/adapter"
+	"iugstav.ipasques/extract"
 )
 
 const (
@@ -97,11 +100,21 @@ type Frontier struct {
 	visited      map[string]struct{}
 	policies     map[string]*Policies
 	defaultDelay time.Duration
+	store        Store
+	robots       *RobotsCache
 	mu           sync.Mutex
 	cond         *sync.Cond
 	closed       bool
 }
 
+// UseRobots attaches a RobotsCache so Add rejects URLs disallowed for our
+// UA and seeds any Sitemap: URLs the target domain advertises.
+func (f *Frontier) UseRobots(rc *RobotsCache) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.robots = rc
+}
+
 func NewCrawlerFrontier(delay time.Duration) *Frontier {
 	pq := make(PQueue, 0)
 	heap.Init(&pq)
@@ -115,11 +128,61 @@ func NewCrawlerFrontier(delay time.Duration) *Frontier {
 	return f
 }
 
+// NewPersistentFrontier builds a Frontier backed by store: pending items and
+// the visited set are reloaded into memory so a crawl can resume where a
+// previous run left off, and every subsequent Add/Next transactionally
+// updates store as well as the in-memory heap.
+func NewPersistentFrontier(delay time.Duration, store Store) (*Frontier, error) {
+	f := NewCrawlerFrontier(delay)
+	f.store = store
+
+	visited, err := store.LoadVisited()
+	if err != nil {
+		return nil, fmt.Errorf("robot: loading visited set: %w", err)
+	}
+	f.visited = visited
+
+	policies, err := store.LoadPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("robot: loading policies: %w", err)
+	}
+	for domain, snap := range policies {
+		f.policies[domain] = &Policies{LastRequestTime: snap.LastRequestTime, Delay: snap.Delay}
+	}
+
+	pending, err := store.LoadPending()
+	if err != nil {
+		return nil, fmt.Errorf("robot: loading pending queue: %w", err)
+	}
+	for _, item := range pending {
+		heap.Push(f.queue, item)
+		if _, exists := f.policies[item.Domain]; !exists {
+			f.policies[item.Domain] = &Policies{Delay: f.defaultDelay}
+		}
+	}
+
+	return f, nil
+}
+
 func (f *Frontier) Add(item *Item) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
+	rc := f.robots
+	f.mu.Unlock()
+
+	var newDomainSitemaps []string
+	if rc != nil {
+		allowed, err := rc.Allowed(item.URL)
+		if err != nil {
+			log.Printf("robot: robots.txt check for %s: %v", item.URL, err)
+		} else if !allowed {
+			return
+		}
+	}
+
+	f.mu.Lock()
 
 	if _, seen := f.visited[item.URL]; seen {
+		f.mu.Unlock()
 		return
 	}
 
@@ -127,12 +190,33 @@ func (f *Frontier) Add(item *Item) {
 	f.visited[item.URL] = struct{}{}
 
 	if _, exists := f.policies[item.Domain]; !exists {
-		f.policies[item.Domain] = &Policies{
-			Delay: f.defaultDelay,
+		delay := f.defaultDelay
+		if rc != nil {
+			if crawlDelay, ok := rc.CrawlDelay(item.URL); ok {
+				delay = crawlDelay
+			}
+			newDomainSitemaps = rc.Sitemaps(item.URL)
+		}
+		f.policies[item.Domain] = &Policies{Delay: delay}
+	}
+
+	if f.store != nil {
+		if err := f.store.PutVisited(item.URL); err != nil {
+			log.Printf("robot: persisting visited URL %s: %v", item.URL, err)
+		}
+		if err := f.store.PutPending(item); err != nil {
+			log.Printf("robot: persisting pending item %s: %v", item.URL, err)
 		}
 	}
 
 	f.cond.Signal()
+	f.mu.Unlock()
+
+	for _, sitemapURL := range newDomainSitemaps {
+		if sitemapItem, err := NewItem(sitemapURL, 0); err == nil {
+			f.Add(sitemapItem)
+		}
+	}
 }
 
 func (f *Frontier) Next() *Item {
@@ -152,6 +236,12 @@ func (f *Frontier) Next() *Item {
 	}
 
 	item := heap.Pop(f.queue).(*Item)
+	if f.store != nil {
+		if err := f.store.DeletePending(item.URL); err != nil {
+			log.Printf("robot: clearing pending item %s: %v", item.URL, err)
+		}
+	}
+
 	policy := f.policies[item.Domain]
 	policy.mu.Lock()
 	defer policy.mu.Unlock()
@@ -166,9 +256,40 @@ func (f *Frontier) Next() *Item {
 	}
 	policy.LastRequestTime = time.Now()
 
+	if f.store != nil {
+		if err := f.store.PutPolicy(item.Domain, policySnapshot{LastRequestTime: policy.LastRequestTime, Delay: policy.Delay}); err != nil {
+			log.Printf("robot: persisting policy for %s: %v", item.Domain, err)
+		}
+	}
+
 	return item
 }
 
+// Checkpoint flushes the current per-domain policy state to the store. It
+// is a no-op when the Frontier has no Store attached, and is meant to be
+// called periodically (e.g. from a ticker in main) on long crawls so a
+// crash loses at most one checkpoint interval of delay bookkeeping.
+func (f *Frontier) Checkpoint() error {
+	if f.store == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for domain, policy := range f.policies {
+		policy.mu.Lock()
+		snap := policySnapshot{LastRequestTime: policy.LastRequestTime, Delay: policy.Delay}
+		policy.mu.Unlock()
+
+		if err := f.store.PutPolicy(domain, snap); err != nil {
+			return fmt.Errorf("robot: checkpointing policy for %s: %w", domain, err)
+		}
+	}
+
+	return nil
+}
+
 func (f *Frontier) Close() {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -176,6 +297,16 @@ func (f *Frontier) Close() {
 	f.cond.Broadcast()
 }
 
+// Pending reports how many items are currently queued, without popping
+// anything off. Callers can use this after NewPersistentFrontier to tell
+// whether the store already has work left over from a previous run, e.g.
+// to decide whether re-seeding is needed.
+func (f *Frontier) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queue.Len()
+}
+
 type Crawler struct {
 	launcherURL string
 	Pool        rod.Pool[rod.Browser]
@@ -206,63 +337,112 @@ func (c *Crawler) GetBrowser() *rod.Browser {
 	})
 }
 
-func (c *Crawler) GetTags(frontier *Frontier) {
-	fmt.Println("Getting popular tags")
-
-	page := c.GetBrowser().MustPage("https://dev.to/tags").MustWaitLoad()
-	links := page.MustElements("a[href^='/t/']")
-	for _, l := range links {
-		relativeLink, err := l.Attribute("href")
-		if err != nil || relativeLink == nil {
-			log.Println(err)
-			continue
-		}
-
-		absoluteURL, err := _normalizeURL("https://dev.to/", *relativeLink)
+// GetTags seeds frontier from every adapter registered in registry, visiting
+// each adapter's SeedURLs and following its LinkSelector to the tag/article
+// links a fresh crawl of that platform should start from.
+func (c *Crawler) GetTags(frontier *Frontier, registry *adapter.Registry) {
+	for _, a := range registry.All() {
+		seeds, err := a.SeedURLs(context.Background())
 		if err != nil {
 			log.Println(err)
 			continue
 		}
 
-		if item, err := NewItem(absoluteURL, 0); err == nil {
-			frontier.Add(item)
+		for _, seed := range seeds {
+			fmt.Printf("Getting seed links from %s\n", seed)
+
+			page := c.GetBrowser().MustPage(seed).MustWaitLoad()
+			links := page.MustElements(a.LinkSelector())
+			for _, l := range links {
+				relativeLink, err := l.Attribute("href")
+				if err != nil || relativeLink == nil {
+					log.Println(err)
+					continue
+				}
+
+				absoluteURL, err := _normalizeURL(seed, *relativeLink)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+
+				if item, err := NewItem(absoluteURL, 0); err == nil {
+					frontier.Add(item)
+				}
+			}
 		}
 	}
 }
 
-func ProcessTag(id int, item *Item, frontier *Frontier, browser *rod.Browser, writer *URLWriter) {
+// ProcessTag renders item.URL, scrolls it to the bottom and hands every
+// navigational link found on it to writer. When registry has an adapter
+// matching item.URL, that adapter's LinkSelector takes precedence over the
+// generic extract.ExtractHTML classification. It returns an error rather
+// than just logging one so callers can track success/failure counts (e.g.
+// for a progress reporter). ctx is checked before the page is even created
+// and bound to the page itself, so a cancellation (e.g. a forced shutdown)
+// aborts an in-flight navigation instead of leaving it to run to completion
+// against resources the caller may already be tearing down.
+func ProcessTag(ctx context.Context, id int, item *Item, frontier *Frontier, browser *rod.Browser, writer *URLWriter, registry *adapter.Registry) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	page, err := browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
-		fmt.Println(fmt.Errorf("Worker %d [depth %d] | error creating browser page: %v", id, item.Depth, err))
-		return
+		return fmt.Errorf("Worker %d [depth %d] | error creating browser page: %w", id, item.Depth, err)
 	}
 	defer page.MustClose()
+	page = page.Context(ctx)
 	page.
 		MustSetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: PickUA()}).
 		MustSetViewport(1920, 1080, 1, false)
 
 	if err = rod.Try(func() { page.MustNavigate(item.URL).MustWaitLoad() }); err != nil {
-		fmt.Println(fmt.Errorf("Worker %d [depth %d] | error navigating to page: %v", id, item.Depth, err))
-		return
+		return fmt.Errorf("Worker %d [depth %d] | error navigating to page: %w", id, item.Depth, err)
 	}
 	page.MustWaitIdle()
 
 	infiniteScroll(page)
-	links := page.MustElements("a[aria-labelledby]")
-	for _, link := range links {
-		attr, err := link.Attribute("href")
-		if err != nil || attr == nil {
-			continue
+
+	if siteAdapter, err := registry.For(item.URL); err == nil {
+		links := page.MustElements(siteAdapter.LinkSelector())
+		for _, l := range links {
+			relativeLink, err := l.Attribute("href")
+			if err != nil || relativeLink == nil {
+				continue
+			}
+
+			absoluteURL, err := _normalizeURL(item.URL, *relativeLink)
+			if err != nil {
+				continue
+			}
+
+			writer.Write(absoluteURL)
 		}
 
-		absoluteURL, err := _normalizeURL(item.URL, *attr)
-		if err != nil {
-			fmt.Println(err)
+		return nil
+	}
+
+	base, err := url.Parse(item.URL)
+	if err != nil {
+		return err
+	}
+
+	links, err := extract.ExtractHTML([]byte(page.MustHTML()), base)
+	if err != nil {
+		return fmt.Errorf("Worker %d [depth %d] | error extracting links: %w", id, item.Depth, err)
+	}
+
+	for _, link := range links {
+		if link.Asset {
 			continue
 		}
 
-		writer.Write(absoluteURL)
+		writer.Write(link.URL)
 	}
+
+	return nil
 }
 
 // func ProcessURL(id int, item *Item, frontier *Frontier, browser *rod.Browser) {
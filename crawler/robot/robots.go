@@ -0,0 +1,287 @@
+package robot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent identifies this crawler to robots.txt and is matched
+// case-insensitively against each file's `User-agent:` groups.
+const DefaultUserAgent = "ipasquesbot"
+
+// robotsRules is the parsed form of a single domain's /robots.txt, reduced
+// to the directives that apply to our UA (falling back to "*").
+type robotsRules struct {
+	allow      []string
+	disallow   []string
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+type robotsEntry struct {
+	rules     *robotsRules
+	fetchedAt time.Time
+}
+
+// RobotsCache fetches and caches each domain's robots.txt with a TTL-based
+// refresh, so Frontier.Add can reject disallowed URLs without re-fetching
+// the file on every call.
+type RobotsCache struct {
+	mu        sync.Mutex
+	entries   map[string]*robotsEntry
+	ttl       time.Duration
+	client    *http.Client
+	userAgent string
+	// Bypass skips all robots.txt checks (allow everything); useful in tests
+	// or when crawling infrastructure you control.
+	Bypass bool
+}
+
+// NewRobotsCache builds a cache that refreshes a domain's robots.txt once
+// ttl has elapsed since it was last fetched.
+func NewRobotsCache(userAgent string, ttl time.Duration) *RobotsCache {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return &RobotsCache{
+		entries:   make(map[string]*robotsEntry),
+		ttl:       ttl,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		userAgent: userAgent,
+	}
+}
+
+// Allowed reports whether rawURL may be fetched according to the target
+// domain's robots.txt, fetching and caching it first if needed.
+func (c *RobotsCache) Allowed(rawURL string) (bool, error) {
+	if c.Bypass {
+		return true, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+
+	rules, err := c.rulesFor(u)
+	if err != nil {
+		// A robots.txt we can't fetch or parse shouldn't block the crawl;
+		// fail open, same as most well-behaved crawlers do.
+		return true, nil
+	}
+
+	return rules.allows(u.EscapedPath()), nil
+}
+
+// CrawlDelay returns the Crawl-delay directive for rawURL's domain, if any.
+func (c *RobotsCache) CrawlDelay(rawURL string) (time.Duration, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, false
+	}
+
+	rules, err := c.rulesFor(u)
+	if err != nil || rules.crawlDelay == 0 {
+		return 0, false
+	}
+
+	return rules.crawlDelay, true
+}
+
+// Sitemaps returns the Sitemap: URLs declared in rawURL's domain's
+// robots.txt, if any.
+func (c *RobotsCache) Sitemaps(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	rules, err := c.rulesFor(u)
+	if err != nil {
+		return nil
+	}
+
+	return rules.sitemaps
+}
+
+func (c *RobotsCache) rulesFor(u *url.URL) (*robotsRules, error) {
+	if c.Bypass {
+		return &robotsRules{}, nil
+	}
+
+	host := u.Hostname()
+
+	c.mu.Lock()
+	entry, exists := c.entries[host]
+	c.mu.Unlock()
+
+	if exists && time.Since(entry.fetchedAt) < c.ttl {
+		return entry.rules, nil
+	}
+
+	rules, err := c.fetch(u)
+	if err != nil {
+		if exists {
+			// Serve the stale copy rather than fail the whole lookup.
+			return entry.rules, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = &robotsEntry{rules: rules, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return rules, nil
+}
+
+func (c *RobotsCache) fetch(u *url.URL) (*robotsRules, error) {
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", u.Scheme, u.Host)
+
+	req, err := http.NewRequest("GET", robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		// No robots.txt (or it errored): everything is allowed.
+		return &robotsRules{}, nil
+	}
+
+	return parseRobots(resp.Body, c.userAgent)
+}
+
+func (rules *robotsRules) allows(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+
+	var matchedRule string
+	var matchedAllow bool
+
+	for _, p := range rules.disallow {
+		if strings.HasPrefix(path, p) && len(p) > len(matchedRule) {
+			matchedRule = p
+			matchedAllow = false
+		}
+	}
+	for _, p := range rules.allow {
+		if strings.HasPrefix(path, p) && len(p) > len(matchedRule) {
+			matchedRule = p
+			matchedAllow = true
+		}
+	}
+
+	if matchedRule == "" {
+		return true
+	}
+	return matchedAllow
+}
+
+// parseRobots parses a robots.txt body, keeping only the directives from
+// the most specific group matching userAgent (falling back to "*").
+func parseRobots(body io.Reader, userAgent string) (*robotsRules, error) {
+	ua := strings.ToLower(userAgent)
+
+	type group struct {
+		agents     []string
+		allow      []string
+		disallow   []string
+		crawlDelay time.Duration
+	}
+
+	var groups []*group
+	var current *group
+	var sitemaps []string
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			if current != nil && len(current.allow)+len(current.disallow) > 0 {
+				current = nil
+			}
+			if current == nil {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "disallow":
+			if current != nil && value != "" {
+				current.disallow = append(current.disallow, value)
+			}
+		case "allow":
+			if current != nil && value != "" {
+				current.allow = append(current.allow, value)
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil {
+					current.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	rules := &robotsRules{sitemaps: sitemaps}
+
+	var wildcard, best *group
+	bestLen := 0
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = g
+			} else if strings.Contains(ua, agent) && len(agent) > bestLen {
+				best, bestLen = g, len(agent)
+			}
+		}
+	}
+
+	if best == nil {
+		best = wildcard
+	}
+	if best != nil {
+		rules.allow = best.allow
+		rules.disallow = best.disallow
+		rules.crawlDelay = best.crawlDelay
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,149 @@
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketVisited  = []byte("visited")
+	bucketPending  = []byte("pending")
+	bucketPolicies = []byte("policies")
+)
+
+// policySnapshot is the on-disk representation of a Policies value.
+type policySnapshot struct {
+	LastRequestTime time.Time     `json:"last_request_time"`
+	Delay           time.Duration `json:"delay"`
+}
+
+// Store persists a Frontier's visited set, pending queue and per-domain
+// policies so a crawl can be resumed after a crash or a planned restart.
+type Store interface {
+	// LoadPending returns every item that was queued but not yet popped.
+	LoadPending() ([]*Item, error)
+	// LoadVisited returns the full set of canonical URLs already seen.
+	LoadVisited() (map[string]struct{}, error)
+	// LoadPolicies returns the last known per-domain policy state.
+	LoadPolicies() (map[string]policySnapshot, error)
+
+	// PutPending persists a newly queued item.
+	PutPending(item *Item) error
+	// DeletePending removes an item once it has been popped off the heap.
+	DeletePending(url string) error
+	// PutVisited marks url as seen.
+	PutVisited(url string) error
+	// PutPolicy persists the current policy state for domain.
+	PutPolicy(domain string, snap policySnapshot) error
+
+	Close() error
+}
+
+// BoltStore is a Store backed by an embedded BoltDB file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path and
+// ensures the buckets used by the Frontier exist.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("robot: opening store at %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketVisited, bucketPending, bucketPolicies} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) LoadPending() ([]*Item, error) {
+	var items []*Item
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPending).ForEach(func(_, v []byte) error {
+			item := &Item{}
+			if err := json.Unmarshal(v, item); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+	})
+	return items, err
+}
+
+func (s *BoltStore) LoadVisited() (map[string]struct{}, error) {
+	visited := make(map[string]struct{})
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketVisited).ForEach(func(k, _ []byte) error {
+			visited[string(k)] = struct{}{}
+			return nil
+		})
+	})
+	return visited, err
+}
+
+func (s *BoltStore) LoadPolicies() (map[string]policySnapshot, error) {
+	policies := make(map[string]policySnapshot)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPolicies).ForEach(func(k, v []byte) error {
+			var snap policySnapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return err
+			}
+			policies[string(k)] = snap
+			return nil
+		})
+	})
+	return policies, err
+}
+
+func (s *BoltStore) PutPending(item *Item) error {
+	v, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPending).Put([]byte(item.URL), v)
+	})
+}
+
+func (s *BoltStore) DeletePending(url string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPending).Delete([]byte(url))
+	})
+}
+
+func (s *BoltStore) PutVisited(url string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketVisited).Put([]byte(url), []byte{1})
+	})
+}
+
+func (s *BoltStore) PutPolicy(domain string, snap policySnapshot) error {
+	v, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPolicies).Put([]byte(domain), v)
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,172 @@
+// Package warc writes fetched pages as gzipped WARC 1.1 records so a crawl
+// can be replayed or re-archived without hitting the origin again.
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	version        = "WARC/1.1"
+	fieldsMIME     = "application/warc-fields"
+	httpRecordMIME = "application/http; msgtype=%s"
+)
+
+// Writer emits gzipped WARC 1.1 records to a rotating set of files under
+// dir. It is safe for concurrent use by multiple workers.
+type Writer struct {
+	mu sync.Mutex
+
+	dir     string
+	prefix  string
+	maxSize int64
+
+	file        *os.File
+	gz          *gzip.Writer
+	buf         *bufio.Writer
+	written     int64
+	fileIndex   int
+	infoEmitted bool
+}
+
+// NewWriter creates a Writer that rotates to a new file once maxSize bytes
+// of uncompressed record data have been written to the current one (0
+// disables rotation). The compressed file on disk is smaller than that.
+func NewWriter(dir, prefix string, maxSize int64) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	w := &Writer{dir: dir, prefix: prefix, maxSize: maxSize}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) rotate() error {
+	if w.gz != nil {
+		if err := w.closeCurrent(); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(w.dir, fmt.Sprintf("%s-%05d.warc.gz", w.prefix, w.fileIndex))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	w.file = f
+	w.gz = gzip.NewWriter(f)
+	w.buf = bufio.NewWriter(w.gz)
+	w.written = 0
+	w.fileIndex++
+	w.infoEmitted = false
+
+	return w.writeWarcinfo()
+}
+
+func (w *Writer) closeCurrent() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *Writer) writeWarcinfo() error {
+	if w.infoEmitted {
+		return nil
+	}
+
+	body := []byte("software: ipasques-crawler\r\nformat: WARC File Format 1.1\r\n")
+	if err := w.writeRecord("warcinfo", "", fieldsMIME, body); err != nil {
+		return err
+	}
+	w.infoEmitted = true
+
+	return nil
+}
+
+// WriteExchange records the request/response pair for a single fetch as a
+// `request` record followed by a `response` record.
+func (w *Writer) WriteExchange(targetURL string, req *http.Request, resp *http.Response, body []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	reqDump, err := httputil.DumpRequestOut(req, false)
+	if err != nil {
+		return fmt.Errorf("warc: dumping request: %w", err)
+	}
+
+	respHead, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		return fmt.Errorf("warc: dumping response: %w", err)
+	}
+	respDump := append(respHead, body...)
+
+	if err := w.writeRecord("request", targetURL, fmt.Sprintf(httpRecordMIME, "request"), reqDump); err != nil {
+		return err
+	}
+	if err := w.writeRecord("response", targetURL, fmt.Sprintf(httpRecordMIME, "response"), respDump); err != nil {
+		return err
+	}
+
+	if w.maxSize > 0 && w.written >= w.maxSize {
+		return w.rotate()
+	}
+
+	return nil
+}
+
+func (w *Writer) writeRecord(recordType, targetURI, contentType string, content []byte) error {
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "%s\r\n", version)
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&header, "WARC-Record-ID: %s\r\n", newRecordID())
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	fmt.Fprintf(&header, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&header, "Content-Length: %d\r\n", len(content))
+	header.WriteString("\r\n")
+
+	n, err := io.Copy(w.buf, io.MultiReader(&header, bytes.NewReader(content), bytes.NewReader([]byte("\r\n\r\n"))))
+	w.written += n
+	return err
+}
+
+func newRecordID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "urn:uuid:00000000-0000-0000-0000-000000000000"
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Close flushes and closes the current WARC file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeCurrent()
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"math/rand/v2"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,6 +21,7 @@ type Item struct {
 	Depth     int
 	Domain    string
 	Timestamp int64
+	Attempts  int // bot-challenge retries spent on this item so far
 	index     int
 }
 
@@ -166,12 +168,55 @@ func (f *Frontier) Close() {
 	f.cond.Broadcast()
 }
 
+// Requeue puts item back onto the queue without touching the visited set,
+// so a retry (e.g. after failing a bot-detection challenge) doesn't trip
+// Add's one-time-per-URL dedupe. It does nothing once the Frontier has been
+// closed.
+func (f *Frontier) Requeue(item *Item) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	heap.Push(f.queue, item)
+	f.cond.Signal()
+}
+
+// proxyBrowser is a browser launched with a specific --proxy-server flag,
+// kept around so every worker assigned that proxy reuses the same
+// Chromium process instead of relaunching one per page.
+type proxyBrowser struct {
+	launcherURL string
+	pool        rod.Pool[rod.Browser]
+}
+
 type Crawler struct {
 	launcherURL string
 	pool        rod.Pool[rod.Browser]
+	proxyPool   *ProxyPool
+
+	proxyMu       sync.Mutex
+	proxyBrowsers map[string]*proxyBrowser
 }
 
+// uaProvider backs pickUA() with a UA distribution kept fresh from
+// useragents.me; InitCrawler is responsible for setting it up.
+var uaProvider *UserAgentProvider
+
+const uaRefreshInterval = 6 * time.Hour
+const uaCachePath = "useragents_cache.json"
+
 func InitCrawler() *Crawler {
+	uaProvider = NewUserAgentProvider(uaRefreshInterval, uaCachePath)
+
+	proxyPool, err := NewProxyPoolFromEnv()
+	if err != nil {
+		fmt.Println(fmt.Errorf("proxy: %w", err))
+		proxyPool = &ProxyPool{}
+	}
+
 	launch := launcher.New().
 		Headless(true).
 		UserDataDir("browser_data").
@@ -181,8 +226,10 @@ func InitCrawler() *Crawler {
 	url := launch.MustLaunch()
 	pool := rod.NewBrowserPool(WORKER_COUNT)
 	c := Crawler{
-		launcherURL: url,
-		pool:        pool,
+		launcherURL:   url,
+		pool:          pool,
+		proxyPool:     proxyPool,
+		proxyBrowsers: make(map[string]*proxyBrowser),
 	}
 
 	return &c
@@ -196,6 +243,73 @@ func (c *Crawler) GetBrowser() *rod.Browser {
 	})
 }
 
+// GetBrowserFor returns a browser dedicated to proxy's address, launching
+// one (and caching it for reuse by later workers assigned the same proxy)
+// the first time that proxy is requested. A nil proxy falls back to the
+// default, direct-connection pool.
+func (c *Crawler) GetBrowserFor(proxy *Proxy) *rod.Browser {
+	if proxy == nil {
+		return c.GetBrowser()
+	}
+
+	key := proxy.Address()
+
+	c.proxyMu.Lock()
+	pb, exists := c.proxyBrowsers[key]
+	if !exists {
+		launch := launcher.New().
+			Headless(true).
+			UserDataDir("browser_data_proxy_" + url.QueryEscape(key)).
+			Set("disable-web-security").
+			Set("disable-notifications").
+			Proxy(key)
+
+		pb = &proxyBrowser{
+			launcherURL: launch.MustLaunch(),
+			pool:        rod.NewBrowserPool(WORKER_COUNT),
+		}
+		c.proxyBrowsers[key] = pb
+	}
+	c.proxyMu.Unlock()
+
+	return pb.pool.MustGet(func() *rod.Browser {
+		b := rod.New().ControlURL(pb.launcherURL).MustConnect()
+
+		if user := proxy.URL.User; user != nil {
+			username := user.Username()
+			password, _ := user.Password()
+			go func() {
+				for b.HandleAuth(username, password)() == nil {
+				}
+			}()
+		}
+
+		return b
+	})
+}
+
+// PutBrowser returns browser to whichever pool GetBrowserFor handed it out
+// from (proxy's dedicated pool, or the default direct-connection pool), so
+// rotating a worker's session doesn't permanently drain that pool's fixed
+// capacity. Callers must pass the same proxy they requested the browser
+// with.
+func (c *Crawler) PutBrowser(proxy *Proxy, browser *rod.Browser) {
+	if proxy == nil {
+		c.pool.Put(browser)
+		return
+	}
+
+	key := proxy.Address()
+
+	c.proxyMu.Lock()
+	pb, exists := c.proxyBrowsers[key]
+	c.proxyMu.Unlock()
+
+	if exists {
+		pb.pool.Put(browser)
+	}
+}
+
 func (c *Crawler) GetTags(frontier *Frontier) {
 	fmt.Println("Getting popular tags")
 
@@ -220,25 +334,120 @@ func (c *Crawler) GetTags(frontier *Frontier) {
 	}
 }
 
-func ProcessTag(id int, item *Item, frontier *Frontier, browser *rod.Browser) {
+// uaOverrideFor picks a fresh UA matching profile and builds the full
+// Network.setUserAgentOverride payload for it.
+func uaOverrideFor(profile UAProfile) *proto.NetworkSetUserAgentOverride {
+	return buildUAOverride(profile, pickUAFor(profile))
+}
+
+// buildUAOverride builds the Network.setUserAgentOverride payload for ua,
+// so navigator.platform, Accept-Language and (for Chromium browsers) the
+// Sec-CH-UA hints stay consistent with the UA string instead of leaking
+// the launcher's real platform underneath a spoofed UA.
+func buildUAOverride(profile UAProfile, ua string) *proto.NetworkSetUserAgentOverride {
+	override := &proto.NetworkSetUserAgentOverride{
+		UserAgent:      ua,
+		AcceptLanguage: "en-US,en;q=0.9",
+		Platform:       navigatorPlatform(profile.OS),
+	}
+
+	if isChromiumFamily(profile.Browser) {
+		_, _, version := parseUA(ua)
+		major := strconv.Itoa(version)
+
+		override.UserAgentMetadata = &proto.EmulationUserAgentMetadata{
+			Brands: []*proto.EmulationUserAgentBrandVersion{
+				{Brand: profile.Browser, Version: major},
+				{Brand: "Chromium", Version: major},
+				{Brand: "Not=A?Brand", Version: "24"},
+			},
+			Platform: profile.OS,
+		}
+	}
+
+	return override
+}
+
+// ProcessResult summarizes how ProcessTag's visit to a single frontier item
+// went, so callers have enough to both decide whether to rotate the session
+// and to record a URLRecord without re-deriving that state themselves.
+type ProcessResult struct {
+	Challenged bool
+	StatusCode int
+	FinalURL   string
+	Error      string
+}
+
+// pageStatusCode reads the HTTP status of page's last navigation off the
+// Navigation Timing API, the same document.title-style Eval idiom
+// detectChallenge already uses, since rod doesn't surface it directly. It
+// returns 0 if the browser doesn't report one.
+func pageStatusCode(page *rod.Page) int {
+	res, err := page.Eval(`() => performance.getEntriesByType('navigation')[0]?.responseStatus || 0`)
+	if err != nil {
+		return 0
+	}
+	return int(res.Value.Int())
+}
+
+// ProcessTag renders item.URL in browser using session's paired UA (and, by
+// extension, whatever proxy session.Proxy assigned the browser to), and
+// reports the outcome so the caller knows whether to rotate the session and
+// what to record for the URL.
+func ProcessTag(id int, item *Item, frontier *Frontier, browser *rod.Browser, session *Session) ProcessResult {
 	fmt.Printf("Worker %d at tag %s\n", id, item.URL)
 
 	page, err := browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
 		fmt.Println(fmt.Errorf("Worker %d [depth %d] | error creating browser page: %v", id, item.Depth, err))
-		return
+		return ProcessResult{Error: err.Error()}
 	}
 	defer page.MustClose()
 	page.
-		MustSetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: pickUA()}).
+		MustSetUserAgent(buildUAOverride(session.Profile, session.UA)).
 		MustSetViewport(1920, 1080, 1, false)
 
+	if cached := clearance.Get(item.Domain); len(cached) > 0 {
+		page.MustSetCookies(cookiesToParams(cached)...)
+	}
+
+	start := time.Now()
 	if err = rod.Try(func() { page.MustNavigate(item.URL).MustWaitLoad() }); err != nil {
 		fmt.Println(fmt.Errorf("Worker %d [depth %d] | error navigating to page: %v", id, item.Depth, err))
-		return
+		if session.Proxy != nil {
+			session.Proxy.reportFailure()
+		}
+		return ProcessResult{Error: err.Error()}
 	}
 	page.MustWaitIdle()
 
+	if kind := detectChallenge(page); kind != ChallengeNone {
+		fmt.Printf("Worker %d [depth %d] | %s challenge detected on %s, waiting for clearance\n", id, item.Depth, kind, item.URL)
+
+		if !awaitClearance(page, kind) {
+			if session.Proxy != nil {
+				session.Proxy.reportFailure()
+			}
+			requeueAfterChallenge(frontier, item)
+			return ProcessResult{Challenged: true}
+		}
+
+		clearance.Put(item.Domain, page.MustCookies())
+
+		if err = rod.Try(func() { page.MustNavigate(item.URL).MustWaitLoad() }); err != nil {
+			fmt.Println(fmt.Errorf("Worker %d [depth %d] | error re-navigating after challenge clearance: %v", id, item.Depth, err))
+			requeueAfterChallenge(frontier, item)
+			return ProcessResult{Challenged: true, Error: err.Error()}
+		}
+		page.MustWaitIdle()
+
+		return ProcessResult{Challenged: true, StatusCode: pageStatusCode(page), FinalURL: page.MustInfo().URL}
+	}
+
+	if session.Proxy != nil {
+		session.Proxy.reportSuccess(time.Since(start))
+	}
+
 	infiniteScroll(page)
 
 	links := page.MustElements("a[aria-labelledby]")
@@ -248,6 +457,8 @@ func ProcessTag(id int, item *Item, frontier *Frontier, browser *rod.Browser) {
 			continue
 		}
 	}
+
+	return ProcessResult{StatusCode: pageStatusCode(page), FinalURL: page.MustInfo().URL}
 }
 
 // func ProcessURL(id int, item *Item, frontier *Frontier, browser *rod.Browser) {
@@ -286,6 +497,12 @@ func ProcessTag(id int, item *Item, frontier *Frontier, browser *rod.Browser) {
 
 func (c *Crawler) Cleanup() {
 	c.pool.Cleanup(func(p *rod.Browser) { p.MustClose() })
+
+	c.proxyMu.Lock()
+	defer c.proxyMu.Unlock()
+	for _, pb := range c.proxyBrowsers {
+		pb.pool.Cleanup(func(p *rod.Browser) { p.MustClose() })
+	}
 }
 
 func infiniteScroll(page *rod.Page) {
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ const (
 	WORKER_COUNT = 100 // one for each tag
 	MAX_DEPTH    = 3
 	TIMEOUT      = 10 * time.Second
+	debugAddr    = ":6060"
 )
 
 func main() {
@@ -31,14 +33,22 @@ func main() {
 
 	crawler.GetTags(frontier)
 
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/proxies", crawler.proxyPool)
+		fmt.Println(http.ListenAndServe(debugAddr, mux))
+	}()
+
 	var wg sync.WaitGroup
 	for i := range WORKER_COUNT {
 		wg.Add(1)
 		go func(id int, wg *sync.WaitGroup) {
 			defer wg.Done()
 
-			browser := crawler.GetBrowser()
-			defer crawler.pool.Put(browser)
+			session := newSession(crawler.proxyPool, defaultUAProfile)
+			browser := crawler.GetBrowserFor(session.Proxy)
+			defer func() { crawler.PutBrowser(session.Proxy, browser) }()
+
 			for {
 				item := frontier.Next()
 				if item == nil {
@@ -47,7 +57,28 @@ func main() {
 				}
 
 				fmt.Printf("Worker %d at tag %s\n", id, item.URL)
-				ProcessTag(id, item, frontier, browser, w)
+				result := ProcessTag(id, item, frontier, browser, session)
+				session.pages++
+
+				rec := URLRecord{
+					URL:        item.URL,
+					Tag:        item.Domain,
+					Depth:      item.Depth,
+					UAUsed:     session.UA,
+					StatusCode: result.StatusCode,
+					FinalURL:   result.FinalURL,
+					Error:      result.Error,
+				}
+				if session.Proxy != nil {
+					rec.ProxyUsed = session.Proxy.Address()
+				}
+				w.Write(rec)
+
+				if session.due(result.Challenged) {
+					crawler.PutBrowser(session.Proxy, browser)
+					session = newSession(crawler.proxyPool, defaultUAProfile)
+					browser = crawler.GetBrowserFor(session.Proxy)
+				}
 			}
 		}(i, &wg)
 	}
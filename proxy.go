@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	proxyListPath   = "proxies.txt"
+	proxyListEnvVar = "PROXY_LIST"
+
+	proxyBaseBackoff = 30 * time.Second
+	proxyMaxBackoff  = 30 * time.Minute
+
+	// sessionPageLimit is how many pages a (proxy, UA) pairing serves
+	// before it's rotated for a fresh one, even without a challenge.
+	sessionPageLimit = 25
+)
+
+// Proxy is one entry in a ProxyPool, along with the health bookkeeping used
+// to decide whether it's still worth dialing.
+type Proxy struct {
+	URL *url.URL
+
+	mu                sync.Mutex
+	consecutiveErrors int
+	lastUsed          time.Time
+	avgLatency        time.Duration
+	benchedUntil      time.Time
+}
+
+// Address is the proxy-server value Chromium expects, with any embedded
+// basic-auth credentials stripped (those go through HandleAuth instead).
+func (p *Proxy) Address() string {
+	u := *p.URL
+	u.User = nil
+	return u.String()
+}
+
+func (p *Proxy) benched() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Now().Before(p.benchedUntil)
+}
+
+// reportSuccess clears the proxy's backoff and folds latency into its
+// running average.
+func (p *Proxy) reportSuccess(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.consecutiveErrors = 0
+	p.benchedUntil = time.Time{}
+	p.lastUsed = time.Now()
+	if p.avgLatency == 0 {
+		p.avgLatency = latency
+	} else {
+		p.avgLatency = (p.avgLatency + latency) / 2
+	}
+}
+
+// reportFailure benches the proxy for an exponentially increasing backoff
+// window, so a dead proxy stops being handed out without being removed
+// outright (it may come back).
+func (p *Proxy) reportFailure() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.consecutiveErrors++
+	p.lastUsed = time.Now()
+
+	backoff := time.Duration(float64(proxyBaseBackoff) * math.Pow(2, float64(p.consecutiveErrors-1)))
+	if backoff > proxyMaxBackoff {
+		backoff = proxyMaxBackoff
+	}
+	p.benchedUntil = time.Now().Add(backoff)
+}
+
+// proxyStatus is the JSON shape served by ProxyPool's debug handler.
+type proxyStatus struct {
+	Address           string    `json:"address"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	LastUsed          time.Time `json:"last_used"`
+	AvgLatencyMS      int64     `json:"avg_latency_ms"`
+	BenchedUntil      time.Time `json:"benched_until"`
+	Benched           bool      `json:"benched"`
+}
+
+func (p *Proxy) snapshot() proxyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return proxyStatus{
+		Address:           p.Address(),
+		ConsecutiveErrors: p.consecutiveErrors,
+		LastUsed:          p.lastUsed,
+		AvgLatencyMS:      p.avgLatency.Milliseconds(),
+		BenchedUntil:      p.benchedUntil,
+		Benched:           time.Now().Before(p.benchedUntil),
+	}
+}
+
+// ProxyPool round-robins across a set of outbound proxies, skipping ones
+// that have recently failed (benched with exponential backoff) until they
+// cool down.
+type ProxyPool struct {
+	mu      sync.Mutex
+	proxies []*Proxy
+	next    int
+}
+
+// NewProxyPool loads proxy URIs (http://, https:// or socks5://, with an
+// optional user:pass) from source: a file path if it exists on disk,
+// otherwise a comma-separated list (e.g. read straight from an env var).
+// An empty source yields an empty, harmless pool: callers should treat "no
+// proxies configured" as "go direct".
+func NewProxyPool(source string) (*ProxyPool, error) {
+	if source == "" {
+		return &ProxyPool{}, nil
+	}
+
+	var raw []string
+	if f, err := os.Open(source); err == nil {
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				raw = append(raw, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading proxy list %s: %w", source, err)
+		}
+	} else {
+		for _, entry := range strings.Split(source, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				raw = append(raw, entry)
+			}
+		}
+	}
+
+	pool := &ProxyPool{}
+	for _, entry := range raw {
+		u, err := url.Parse(entry)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URI %q: %w", entry, err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q in %q", u.Scheme, entry)
+		}
+
+		pool.proxies = append(pool.proxies, &Proxy{URL: u})
+	}
+
+	return pool, nil
+}
+
+// NewProxyPoolFromEnv reads proxyListEnvVar, falling back to proxyListPath
+// if that file exists, so InitCrawler can wire a pool without callers
+// having to pick a source explicitly. Neither being set is not an error --
+// it just means the crawl goes direct.
+func NewProxyPoolFromEnv() (*ProxyPool, error) {
+	if list := os.Getenv(proxyListEnvVar); list != "" {
+		return NewProxyPool(list)
+	}
+	if _, err := os.Stat(proxyListPath); err == nil {
+		return NewProxyPool(proxyListPath)
+	}
+	return &ProxyPool{}, nil
+}
+
+// Pick round-robins to the next non-benched proxy. It returns nil if the
+// pool is empty or every proxy is currently benched, in which case the
+// caller should go proxy-less.
+func (pp *ProxyPool) Pick() *Proxy {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	if len(pp.proxies) == 0 {
+		return nil
+	}
+
+	for range pp.proxies {
+		p := pp.proxies[pp.next]
+		pp.next = (pp.next + 1) % len(pp.proxies)
+		if !p.benched() {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// Snapshot returns the health of every configured proxy.
+func (pp *ProxyPool) Snapshot() []proxyStatus {
+	pp.mu.Lock()
+	proxies := make([]*Proxy, len(pp.proxies))
+	copy(proxies, pp.proxies)
+	pp.mu.Unlock()
+
+	statuses := make([]proxyStatus, len(proxies))
+	for i, p := range proxies {
+		statuses[i] = p.snapshot()
+	}
+	return statuses
+}
+
+// ServeHTTP renders the pool's health as JSON. Mount it at /debug/proxies
+// so operators can see which proxies are currently benched.
+func (pp *ProxyPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pp.Snapshot()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Session pairs a proxy with a UA (and therefore its Accept-Language and
+// platform hints) for the lifetime of a worker's browsing run: bot
+// detectors flag a proxy/UA combination that changes on every request far
+// more readily than one that's merely reused across a session.
+type Session struct {
+	Proxy   *Proxy
+	Profile UAProfile
+	UA      string
+	pages   int
+}
+
+// newSession pairs a freshly picked proxy with a UA matching profile.
+func newSession(pool *ProxyPool, profile UAProfile) *Session {
+	return &Session{
+		Proxy:   pool.Pick(),
+		Profile: profile,
+		UA:      pickUAFor(profile),
+	}
+}
+
+// due reports whether this session has served its quota of pages (or just
+// hit a challenge) and should be rotated for a fresh (proxy, UA) pairing.
+func (s *Session) due(challenged bool) bool {
+	return challenged || s.pages >= sessionPageLimit
+}
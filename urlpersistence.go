@@ -1,19 +1,66 @@
 package main
 
 import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
 
+// URLRecord is one entry written by URLWriter: the discovered URL plus
+// enough context about how and when it was found for a downstream pipeline
+// to consume it without re-scraping.
+type URLRecord struct {
+	URL          string    `json:"url"`
+	Tag          string    `json:"tag,omitempty"`
+	Depth        int       `json:"depth"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	FinalURL     string    `json:"final_url,omitempty"`
+	UAUsed       string    `json:"ua_used,omitempty"`
+	ProxyUsed    string    `json:"proxy_used,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// outputFormat selects how URLWriter renders a URLRecord to disk.
+type outputFormat int
+
+const (
+	formatText outputFormat = iota
+	formatJSONL
+)
+
+// formatFromFilename derives the output format from filename's extension,
+// ignoring a trailing .gz (that only controls compression, not encoding).
+func formatFromFilename(filename string) outputFormat {
+	name := strings.TrimSuffix(filename, ".gz")
+	if strings.HasSuffix(name, ".jsonl") {
+		return formatJSONL
+	}
+	return formatText
+}
+
 type URLWriter struct {
-	file      *os.File
-	writeChan chan string
+	file   *os.File
+	gz     *gzip.Writer
+	buf    *bufio.Writer
+	format outputFormat
+
+	writeChan chan URLRecord
 	done      chan struct{}
 	wg        sync.WaitGroup
 }
 
+// InitURLWriter opens filename for append and starts the background batching
+// goroutine. The output format is chosen from filename's extension: ".jsonl"
+// writes one JSON-encoded URLRecord per line, anything else falls back to
+// the original line-delimited plain-URL mode. A trailing ".gz" wraps either
+// mode in gzip streaming.
 func InitURLWriter(filename string) (*URLWriter, error) {
 	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -22,18 +69,32 @@ func InitURLWriter(filename string) (*URLWriter, error) {
 
 	w := &URLWriter{
 		file:      f,
-		writeChan: make(chan string, 1000),
+		format:    formatFromFilename(filename),
+		writeChan: make(chan URLRecord, 1000),
 		done:      make(chan struct{}),
 	}
 
+	var dst io.Writer = f
+	if strings.HasSuffix(filename, ".gz") {
+		w.gz = gzip.NewWriter(f)
+		dst = w.gz
+	}
+	w.buf = bufio.NewWriter(dst)
+
 	w.wg.Add(1)
 	go w.run()
 	return w, nil
 }
 
-func (w *URLWriter) Write(url string) {
+// Write enqueues rec for batched persistence. DiscoveredAt defaults to now
+// if the caller left it zero.
+func (w *URLWriter) Write(rec URLRecord) {
+	if rec.DiscoveredAt.IsZero() {
+		rec.DiscoveredAt = time.Now()
+	}
+
 	select {
-	case w.writeChan <- url:
+	case w.writeChan <- rec:
 	case <-w.done:
 	}
 }
@@ -41,38 +102,74 @@ func (w *URLWriter) Write(url string) {
 func (w *URLWriter) run() {
 	defer w.wg.Done()
 
-	batchWrite := make([]string, 0, 100)
+	batch := make([]URLRecord, 0, 100)
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case url := <-w.writeChan:
-			batchWrite = append(batchWrite, url)
-			if len(batchWrite) >= 100 {
-				w.flush(batchWrite)
-				batchWrite = batchWrite[:0]
+		case rec := <-w.writeChan:
+			batch = append(batch, rec)
+			if len(batch) >= 100 {
+				w.flush(batch)
+				batch = batch[:0]
 			}
 
 		case <-ticker.C:
-			if len(batchWrite) > 0 {
-				w.flush(batchWrite)
-				batchWrite = batchWrite[:0]
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
 			}
 
 		case <-w.done:
-			if len(batchWrite) > 0 {
-				w.flush(batchWrite)
+			// writeChan may still hold records queued before Close was
+			// called: select doesn't favor this case over a pending
+			// writeChan receive, so drain whatever's left before the
+			// final flush instead of risking a race that drops it.
+			for drained := false; !drained; {
+				select {
+				case rec := <-w.writeChan:
+					batch = append(batch, rec)
+				default:
+					drained = true
+				}
+			}
+			if len(batch) > 0 {
+				w.flush(batch)
 			}
 			return
 		}
 	}
 }
 
-func (w *URLWriter) flush(urls []string) {
-	for _, u := range urls {
-		if _, err := w.file.WriteString(u + "\n"); err != nil {
-			fmt.Println(fmt.Errorf("error writing urls to file : %v", err))
+// flush renders a batch through the shared bufio.Writer with a single Flush
+// call, rather than one syscall per record.
+func (w *URLWriter) flush(records []URLRecord) {
+	for _, rec := range records {
+		if w.format == formatJSONL {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				fmt.Println(fmt.Errorf("error encoding url record: %v", err))
+				continue
+			}
+			data = append(data, '\n')
+			if _, err := w.buf.Write(data); err != nil {
+				fmt.Println(fmt.Errorf("error writing url record: %v", err))
+			}
+			continue
+		}
+
+		if _, err := w.buf.WriteString(rec.URL + "\n"); err != nil {
+			fmt.Println(fmt.Errorf("error writing urls to file: %v", err))
+		}
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		fmt.Println(fmt.Errorf("error flushing url batch: %v", err))
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			fmt.Println(fmt.Errorf("error flushing gzip stream: %v", err))
 		}
 	}
 }
@@ -80,5 +177,13 @@ func (w *URLWriter) flush(urls []string) {
 func (w *URLWriter) Close() error {
 	close(w.done)
 	w.wg.Wait()
+
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+
 	return w.file.Close()
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestURLWriterCloseFlushesPendingBatch confirms that Close's done-path
+// flush actually persists records written since the last 100-record or
+// 1-second batch trigger, rather than dropping them when the background
+// goroutine exits mid-batch.
+func TestURLWriterCloseFlushesPendingBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.txt")
+
+	w, err := InitURLWriter(path)
+	if err != nil {
+		t.Fatalf("InitURLWriter: %v", err)
+	}
+
+	want := []string{
+		"https://dev.to/a",
+		"https://dev.to/b",
+		"https://dev.to/c",
+	}
+	for _, u := range want {
+		w.Write(URLRecord{URL: u})
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening output: %v", err)
+	}
+	defer f.Close()
+
+	var got []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scanning output: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d line(s), want %d: %v", len(got), len(want), got)
+	}
+	for i, u := range want {
+		if got[i] != u {
+			t.Errorf("line %d = %q, want %q", i, got[i], u)
+		}
+	}
+}
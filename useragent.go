@@ -7,7 +7,10 @@ type UserAgent struct {
 	Pct float64 // Percentage
 }
 
-var userAgents []UserAgent = []UserAgent{
+// fallbackUserAgents seeds a UserAgentProvider when there's neither a cache
+// file nor network access yet, so a cold start still picks from a
+// weighted-plausible distribution rather than a single fixed UA.
+var fallbackUserAgents []UserAgent = []UserAgent{
 	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/110.0.0.0 Safari/537.36", 37.8271882916},
 	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/110.0.0.0 Safari/537.36 Edg/110.0.1587.63", 14.2696312975},
 	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/110.0.0.0 Safari/537.36", 10.8077680833},
@@ -55,21 +58,12 @@ var userAgents []UserAgent = []UserAgent{
 	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/109.0.0.0 Safari/537.36 Edg/109.0.1518.70", 0.2251618351},
 }
 
+// pickUA returns a weighted-random UA string from uaProvider, falling back
+// to the frozen fallbackUserAgents table if InitCrawler hasn't run yet.
 func pickUA() string {
-	total := 0.0
-	for _, ua := range userAgents {
-		total += ua.Pct
+	if uaProvider == nil {
+		return fallbackUserAgents[rand.IntN(len(fallbackUserAgents))].UA
 	}
 
-	r := rand.Float64() * total
-	index := 0
-	for i, ua := range userAgents {
-		r -= ua.Pct
-		if r <= 0 {
-			index = i
-			break
-		}
-	}
-
-	return userAgents[index].UA
+	return uaProvider.Pick()
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UAProfile describes the browser/platform combination a UA pick must stay
+// consistent with, so the UA string, navigator.platform and Sec-CH-UA hints
+// handed to a page never contradict each other.
+type UAProfile struct {
+	OS         string // "Linux", "Windows", "macOS", ...
+	Browser    string // "Chrome", "Firefox", "Edge", "Safari", "Opera", ...
+	MinVersion int    // 0 means "any version"
+}
+
+// defaultUAProfile matches go-rod's default launcher: headless Chromium on
+// Linux, which is what every worker actually runs as.
+var defaultUAProfile = UAProfile{OS: "Linux", Browser: "Chrome"}
+
+// parsedUA annotates a UserAgent with the OS/browser family/major version
+// parsed out of its string, so the provider can index and filter by
+// UAProfile without re-parsing on every pick.
+type parsedUA struct {
+	UserAgent
+	OS      string
+	Browser string
+	Version int
+}
+
+func parseUA(ua string) (os, browser string, version int) {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	default:
+		os = "Unknown"
+	}
+
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser, version = "Edge", uaVersionAfter(ua, "Edg/")
+	case strings.Contains(ua, "OPR/"):
+		browser, version = "Opera", uaVersionAfter(ua, "OPR/")
+	case strings.Contains(ua, "Firefox/"):
+		browser, version = "Firefox", uaVersionAfter(ua, "Firefox/")
+	case strings.Contains(ua, "Chrome/"):
+		browser, version = "Chrome", uaVersionAfter(ua, "Chrome/")
+	case strings.Contains(ua, "Version/") && strings.Contains(ua, "Safari/"):
+		browser, version = "Safari", uaVersionAfter(ua, "Version/")
+	default:
+		browser = "Other"
+	}
+
+	return os, browser, version
+}
+
+// uaVersionAfter parses the major version number following marker in ua,
+// e.g. uaVersionAfter("... Chrome/110.0.0.0 ...", "Chrome/") == 110.
+func uaVersionAfter(ua, marker string) int {
+	rest, ok := strings.CutPrefix(ua[strings.Index(ua, marker):], marker)
+	if !ok {
+		return 0
+	}
+
+	end := strings.IndexAny(rest, ". ")
+	if end < 0 {
+		end = len(rest)
+	}
+
+	v, _ := strconv.Atoi(rest[:end])
+	return v
+}
+
+// isChromiumFamily reports whether browser sends Sec-CH-UA client hints.
+func isChromiumFamily(browser string) bool {
+	switch browser {
+	case "Chrome", "Edge", "Opera":
+		return true
+	default:
+		return false
+	}
+}
+
+// navigatorPlatform maps a UAProfile's OS to the navigator.platform value
+// real browsers report for it.
+func navigatorPlatform(os string) string {
+	switch os {
+	case "Windows":
+		return "Win32"
+	case "macOS":
+		return "MacIntel"
+	case "Linux":
+		return "Linux x86_64"
+	default:
+		return ""
+	}
+}
+
+// pickUAFor returns a weighted-random UA string matching profile, falling
+// back to the most common UA overall if nothing in the current table
+// matches the requested OS/browser/version combination.
+func pickUAFor(profile UAProfile) string {
+	if uaProvider == nil {
+		return pickUA()
+	}
+
+	return uaProvider.PickFor(profile)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const userAgentsAPI = "https://www.useragents.me/api"
+
+// deadEngines lists substrings of UA strings that Refresh drops from a
+// fetched distribution, because the sites we crawl already treat them as a
+// fingerprinting tell. The built-in fallbackUserAgents seed is not filtered.
+var deadEngines = []string{"Trident"}
+
+// UserAgentProvider keeps a weighted UA distribution fresh by periodically
+// pulling it from useragents.me, while always having a usable table on hand:
+// the last-good snapshot is persisted to cachePath so a cold start without
+// network still picks from a realistic distribution instead of a frozen one.
+type UserAgentProvider struct {
+	client    *http.Client
+	cachePath string
+
+	mu          sync.RWMutex
+	agents      []UserAgent
+	byOSBrowser map[string]map[string][]parsedUA
+	byFrequency []parsedUA
+}
+
+// NewUserAgentProvider seeds the table from cachePath (falling back to a
+// small built-in snapshot if it doesn't exist or can't be read), then
+// refreshes it from the useragents.me API immediately and every refresh
+// interval thereafter.
+func NewUserAgentProvider(refresh time.Duration, cachePath string) *UserAgentProvider {
+	p := &UserAgentProvider{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		cachePath: cachePath,
+		agents:    fallbackUserAgents,
+	}
+
+	if cached, err := p.loadCache(); err == nil && len(cached) > 0 {
+		p.agents = cached
+	}
+	p.rebuildIndex(p.agents)
+
+	if err := p.Refresh(); err != nil {
+		fmt.Println(fmt.Errorf("useragent: initial refresh: %w", err))
+	}
+
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := p.Refresh(); err != nil {
+				fmt.Println(fmt.Errorf("useragent: refresh: %w", err))
+			}
+		}
+	}()
+
+	return p
+}
+
+type uaAPIEntry struct {
+	UA  string `json:"ua"`
+	Pct string `json:"pct"`
+}
+
+type uaAPIResponse struct {
+	Data []uaAPIEntry `json:"data"`
+}
+
+// Refresh pulls the latest distribution from the useragents.me API, drops
+// dead engines, renormalizes the remaining percentages to sum to 100 and
+// atomically swaps the in-memory table. The prior table (and, transitively,
+// the on-disk cache) is left untouched if the fetch fails or comes back
+// empty.
+func (p *UserAgentProvider) Refresh() error {
+	res, err := p.client.Get(userAgentsAPI)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", userAgentsAPI, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: status %d", userAgentsAPI, res.StatusCode)
+	}
+
+	var parsed uaAPIResponse
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return fmt.Errorf("decoding %s: %w", userAgentsAPI, err)
+	}
+
+	agents := make([]UserAgent, 0, len(parsed.Data))
+	for _, entry := range parsed.Data {
+		if isDeadEngine(entry.UA) {
+			continue
+		}
+
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(entry.Pct, "%"), 64)
+		if err != nil {
+			continue
+		}
+
+		agents = append(agents, UserAgent{UA: entry.UA, Pct: pct})
+	}
+
+	if len(agents) == 0 {
+		return fmt.Errorf("useragents.me returned no usable entries")
+	}
+
+	renormalize(agents)
+
+	p.mu.Lock()
+	p.agents = agents
+	p.mu.Unlock()
+	p.rebuildIndex(agents)
+
+	return p.saveCache(agents)
+}
+
+// rebuildIndex re-derives byOSBrowser (a nested map mirroring the
+// categorization capture tools use) and byFrequency (the same agents sorted
+// most-common-first) from agents, so PickFor never has to parse a UA string
+// on the hot path.
+func (p *UserAgentProvider) rebuildIndex(agents []UserAgent) {
+	byOSBrowser := make(map[string]map[string][]parsedUA)
+	byFrequency := make([]parsedUA, 0, len(agents))
+
+	for _, ua := range agents {
+		os, browser, version := parseUA(ua.UA)
+		parsed := parsedUA{UserAgent: ua, OS: os, Browser: browser, Version: version}
+
+		if byOSBrowser[os] == nil {
+			byOSBrowser[os] = make(map[string][]parsedUA)
+		}
+		byOSBrowser[os][browser] = append(byOSBrowser[os][browser], parsed)
+		byFrequency = append(byFrequency, parsed)
+	}
+
+	sort.Slice(byFrequency, func(i, j int) bool { return byFrequency[i].Pct > byFrequency[j].Pct })
+
+	p.mu.Lock()
+	p.byOSBrowser = byOSBrowser
+	p.byFrequency = byFrequency
+	p.mu.Unlock()
+}
+
+// PickFor weighted-randomly selects a UA string matching profile's OS,
+// browser family and minimum version. It falls back to the single
+// most-common UA overall when no entry in the current table matches.
+func (p *UserAgentProvider) PickFor(profile UAProfile) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	candidates := p.byOSBrowser[profile.OS][profile.Browser]
+	if profile.MinVersion > 0 {
+		filtered := make([]parsedUA, 0, len(candidates))
+		for _, c := range candidates {
+			if c.Version >= profile.MinVersion {
+				filtered = append(filtered, c)
+			}
+		}
+		candidates = filtered
+	}
+
+	if len(candidates) == 0 {
+		if len(p.byFrequency) == 0 {
+			return ""
+		}
+		return p.byFrequency[0].UA
+	}
+
+	total := 0.0
+	for _, c := range candidates {
+		total += c.Pct
+	}
+
+	r := rand.Float64() * total
+	for _, c := range candidates {
+		r -= c.Pct
+		if r <= 0 {
+			return c.UA
+		}
+	}
+
+	return candidates[len(candidates)-1].UA
+}
+
+// Pick weighted-randomly selects a UA string from the current table. It
+// takes an RWMutex read lock, so it stays cheap enough for the hot path
+// even while a Refresh is swapping the table out from under it.
+func (p *UserAgentProvider) Pick() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	total := 0.0
+	for _, ua := range p.agents {
+		total += ua.Pct
+	}
+
+	r := rand.Float64() * total
+	index := 0
+	for i, ua := range p.agents {
+		r -= ua.Pct
+		if r <= 0 {
+			index = i
+			break
+		}
+	}
+
+	return p.agents[index].UA
+}
+
+func (p *UserAgentProvider) loadCache() ([]UserAgent, error) {
+	data, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []UserAgent
+	if err := json.Unmarshal(data, &agents); err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+func (p *UserAgentProvider) saveCache(agents []UserAgent) error {
+	data, err := json.Marshal(agents)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(p.cachePath, data, 0644)
+}
+
+func isDeadEngine(ua string) bool {
+	for _, dead := range deadEngines {
+		if strings.Contains(ua, dead) {
+			return true
+		}
+	}
+	return false
+}
+
+func renormalize(agents []UserAgent) {
+	total := 0.0
+	for _, ua := range agents {
+		total += ua.Pct
+	}
+	if total == 0 {
+		return
+	}
+
+	for i := range agents {
+		agents[i].Pct = agents[i].Pct / total * 100
+	}
+}